@@ -0,0 +1,146 @@
+// Package auth menyediakan autentikasi berbasis JWT dan role-based access
+// control, menggantikan perbandingan `secret_id` polos yang sebelumnya
+// dikirim lewat query parameter (dan ikut bocor ke access log).
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Role yang dikenal subsistem ini.
+const (
+	RoleAdmin    = "admin"
+	RoleEmployee = "employee"
+)
+
+// tokenTTL adalah masa berlaku token yang diterbitkan login.
+const tokenTTL = 12 * time.Hour
+
+// Principal adalah identitas pemanggil yang sudah terverifikasi, disuntikkan
+// ke context.Context oleh Middleware.
+type Principal struct {
+	ID   int
+	Role string
+}
+
+type claims struct {
+	EmployeeID int    `json:"employee_id"`
+	Role       string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// HashPassword meng-hash password polos dengan bcrypt untuk disimpan.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("auth: gagal hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// CheckPassword membandingkan password polos dengan hash yang tersimpan.
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// GenerateToken menerbitkan JWT HS256 yang menyatakan identitas dan role
+// pemanggil.
+func GenerateToken(secret string, id int, role string) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+		EmployeeID: id,
+		Role:       role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(tokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	})
+	signed, err := token.SignedString([]byte(secret))
+	if err != nil {
+		return "", fmt.Errorf("auth: gagal menandatangani token: %w", err)
+	}
+	return signed, nil
+}
+
+// ParseToken memverifikasi signature dan masa berlaku token, lalu
+// mengembalikan Principal yang tertanam di dalamnya.
+func ParseToken(secret, tokenString string) (*Principal, error) {
+	parsed, err := jwt.ParseWithClaims(tokenString, &claims{}, func(t *jwt.Token) (any, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("auth: unexpected signing method %v", t.Header["alg"])
+		}
+		return []byte(secret), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("auth: token tidak valid: %w", err)
+	}
+	c, ok := parsed.Claims.(*claims)
+	if !ok || !parsed.Valid {
+		return nil, errors.New("auth: token tidak valid")
+	}
+	return &Principal{ID: c.EmployeeID, Role: c.Role}, nil
+}
+
+type contextKey int
+
+const principalKey contextKey = 0
+
+// FromContext mengambil Principal yang disuntikkan Middleware.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey).(*Principal)
+	return p, ok
+}
+
+// WithPrincipal menyuntikkan Principal ke context.Context tanpa lewat
+// Middleware. Tidak ada pemanggilnya di tree ini.
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+// Middleware mem-parsing header `Authorization: Bearer <token>`, lalu
+// menyuntikkan Principal ke context.Context permintaan. Permintaan tanpa
+// token valid ditolak dengan 401 sebelum mencapai handler.
+func Middleware(secret string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			tokenString, ok := strings.CutPrefix(header, "Bearer ")
+			if !ok || tokenString == "" {
+				http.Error(w, "missing bearer token", http.StatusUnauthorized)
+				return
+			}
+
+			principal, err := ParseToken(secret, tokenString)
+			if err != nil {
+				http.Error(w, "invalid or expired token", http.StatusUnauthorized)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), principalKey, principal)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireRole membungkus sebuah handler agar hanya bisa diakses oleh
+// Principal dengan role yang diberikan. Middleware harus sudah dipasang
+// sebelumnya supaya Principal tersedia di context.
+func RequireRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := FromContext(r.Context())
+			if !ok || principal.Role != role {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package auth
+
+import "testing"
+
+func TestGenerateTokenParseToken_RoundTrip(t *testing.T) {
+	token, err := GenerateToken("test-secret", 7, RoleEmployee)
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+
+	principal, err := ParseToken("test-secret", token)
+	if err != nil {
+		t.Fatalf("ParseToken: %v", err)
+	}
+	if principal.ID != 7 || principal.Role != RoleEmployee {
+		t.Fatalf("ParseToken = %+v, want {ID:7 Role:%s}", principal, RoleEmployee)
+	}
+}
+
+func TestParseToken_RejectsWrongSecret(t *testing.T) {
+	token, err := GenerateToken("correct-secret", 1, RoleAdmin)
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	if _, err := ParseToken("wrong-secret", token); err == nil {
+		t.Fatal("expected token signed with a different secret to be rejected")
+	}
+}
+
+func TestHashPasswordCheckPassword_RoundTrip(t *testing.T) {
+	hash, err := HashPassword("hunter2")
+	if err != nil {
+		t.Fatalf("HashPassword: %v", err)
+	}
+	if !CheckPassword(hash, "hunter2") {
+		t.Fatal("expected correct password to verify")
+	}
+	if CheckPassword(hash, "wrong-password") {
+		t.Fatal("expected incorrect password to be rejected")
+	}
+}
@@ -0,0 +1,155 @@
+// Package config memuat konfigurasi aplikasi dari environment variable,
+// dengan fallback ke file `conf/app.conf` untuk key yang tidak di-set lewat
+// environment (dipakai saat menjalankan secara lokal tanpa orchestrator).
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// RunMode yang didukung lewat RUN_MODE.
+const (
+	RunModeDev  = "dev"
+	RunModeTest = "test"
+	RunModeProd = "prod"
+)
+
+// Config menyimpan seluruh konfigurasi runtime aplikasi. Instance-nya
+// dibuat sekali di main() lewat Load, lalu dioper ke constructor handler
+// alih-alih dibaca dari variabel global.
+type Config struct {
+	AppName  string
+	RunMode  string
+	HTTPPort string
+	LogLevel string
+
+	PostgresUser     string
+	PostgresPassword string
+	PostgresHost     string
+	PostgresPort     string
+	PostgresDB       string
+
+	JWTSecret string
+}
+
+// requiredKeys harus ada (lewat environment ataupun conf/app.conf) sebelum
+// aplikasi boleh berjalan.
+var requiredKeys = []string{
+	"POSTGRESQL_USER",
+	"POSTGRESQL_PASSWORD",
+	"POSTGRESQL_HOST",
+	"POSTGRESQL_PORT",
+	"POSTGRESQL_DB",
+	"JWT_SECRET",
+}
+
+// Load membaca konfigurasi dari environment variable, menimpali key yang
+// kosong dengan isi confPath (format "KEY=value" per baris, boleh tidak
+// ada), lalu memvalidasi bahwa seluruh requiredKeys terisi.
+func Load(confPath string) (*Config, error) {
+	values := readFileValues(confPath)
+	get := func(key string) string {
+		if v := os.Getenv(key); v != "" {
+			return v
+		}
+		return values[key]
+	}
+
+	var missing []string
+	for _, key := range requiredKeys {
+		if get(key) == "" {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("config: missing required keys: %s", strings.Join(missing, ", "))
+	}
+
+	cfg := &Config{
+		AppName:          getOr(get, "APP_NAME", "self-payroll-web-service"),
+		RunMode:          getOr(get, "RUN_MODE", RunModeDev),
+		HTTPPort:         getOr(get, "HTTP_PORT", "8080"),
+		LogLevel:         getOr(get, "LOG_LEVEL", "info"),
+		PostgresUser:     get("POSTGRESQL_USER"),
+		PostgresPassword: get("POSTGRESQL_PASSWORD"),
+		PostgresHost:     get("POSTGRESQL_HOST"),
+		PostgresPort:     get("POSTGRESQL_PORT"),
+		PostgresDB:       get("POSTGRESQL_DB"),
+		JWTSecret:        get("JWT_SECRET"),
+	}
+
+	if err := validatePort(cfg.HTTPPort); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func getOr(get func(string) string, key, fallback string) string {
+	if v := get(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// readFileValues membaca file "KEY=value" sederhana; baris kosong dan yang
+// diawali "#" diabaikan. File yang tidak ada dianggap kosong, bukan error,
+// supaya deployment yang sepenuhnya env-based tidak perlu membuatnya.
+func readFileValues(path string) map[string]string {
+	values := map[string]string{}
+	file, err := os.Open(path)
+	if err != nil {
+		return values
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return values
+}
+
+// ConnString membangun connection string lib/pq dari konfigurasi Postgres.
+func (c *Config) ConnString() string {
+	return fmt.Sprintf(
+		"user=%s password=%s host=%s port=%s dbname=%s sslmode=disable",
+		c.PostgresUser, c.PostgresPassword, c.PostgresHost, c.PostgresPort, c.PostgresDB,
+	)
+}
+
+// Addr mengembalikan address yang dipakai http.ListenAndServe, misal ":8080".
+func (c *Config) Addr() string {
+	if strings.HasPrefix(c.HTTPPort, ":") {
+		return c.HTTPPort
+	}
+	return ":" + c.HTTPPort
+}
+
+// IsProd memudahkan pengecekan RUN_MODE di tempat yang butuh perilaku
+// berbeda antara dev/test dan prod (misal verbosity logging).
+func (c *Config) IsProd() bool {
+	return c.RunMode == RunModeProd
+}
+
+// validatePort memastikan sebuah string port numerik valid; dipakai oleh
+// pemanggil yang ingin fail-fast lebih awal daripada saat listener dibuka.
+func validatePort(port string) error {
+	n, err := strconv.Atoi(port)
+	if err != nil || n <= 0 || n > 65535 {
+		return fmt.Errorf("config: invalid port %q", port)
+	}
+	return nil
+}
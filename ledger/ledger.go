@@ -0,0 +1,125 @@
+// Package ledger menyediakan sistem pencatatan double-entry untuk semua
+// pergerakan saldo perusahaan dan employee. Setiap perubahan saldo dicatat
+// sebagai satu atau lebih Entry yang diposting bersama-sama secara atomic,
+// sehingga saldo tidak pernah disimpan sebagai kolom yang di-mutate langsung
+// melainkan diturunkan (derived) dari penjumlahan entry yang tercatat.
+package ledger
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+)
+
+// Nama akun baku yang dipakai oleh subsistem balance perusahaan.
+const (
+	AccountCompanyCash   = "company_cash"
+	AccountTopUpSource   = "top_up_source"
+	AccountDeductionSink = "deduction_sink"
+)
+
+// Direction menyatakan sisi debit/kredit dari sebuah Entry.
+type Direction string
+
+const (
+	Debit  Direction = "debit"
+	Credit Direction = "credit"
+)
+
+// Entry adalah satu baris posting pada ledger. Entry bersifat immutable:
+// setelah diposting, entry tidak pernah diubah atau dihapus.
+type Entry struct {
+	Account   string
+	Direction Direction
+	Amount    float64
+	Reference string
+}
+
+// EmployeePayableAccount mengembalikan nama akun payable milik employee
+// dengan ID tertentu.
+func EmployeePayableAccount(employeeID int) string {
+	return fmt.Sprintf("employee_payable:%d", employeeID)
+}
+
+// execer disatukan oleh *sql.DB dan *sql.Tx, sehingga PostTransaction bisa
+// dipanggil baik berdiri sendiri maupun sebagai bagian dari transaksi yang
+// lebih besar yang sudah dibuka pemanggil (lihat exec di webhook.Emit untuk
+// pola yang sama). Ini penting supaya audit row non-ledger dan ledger entry
+// untuk satu operasi bisnis selalu commit atau rollback bersama-sama, tidak
+// pernah sebagai dua transaksi terpisah yang bisa desync.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// PostTransaction memposting sekumpulan Entry. Jumlah debit harus sama
+// dengan jumlah kredit, kalau tidak posting ditolak sebelum satu pun entry
+// ditulis. PostTransaction sendiri tidak membuka atau meng-commit transaksi
+// apa pun; exec menentukan atomicity-nya. Pemanggil yang hanya memposting
+// ledger boleh mengoper *sql.DB langsung, pemanggil yang juga menulis audit
+// row/outbox event dalam operasi yang sama harus mengoper *sql.Tx yang sama
+// supaya kedua tulisan itu satu unit atomic.
+func PostTransaction(ctx context.Context, exec execer, entries []Entry) error {
+	if len(entries) == 0 {
+		return fmt.Errorf("ledger: posting tanpa entry")
+	}
+
+	var debit, credit float64
+	for _, e := range entries {
+		switch e.Direction {
+		case Debit:
+			debit += e.Amount
+		case Credit:
+			credit += e.Amount
+		default:
+			return fmt.Errorf("ledger: direction tidak dikenal %q", e.Direction)
+		}
+	}
+	if debit != credit {
+		return fmt.Errorf("ledger: posting tidak balance, debit=%.2f credit=%.2f", debit, credit)
+	}
+
+	insertEntry := `
+		INSERT INTO ledger_entry (account, direction, amount, reference)
+		VALUES ($1, $2, $3, $4)
+	`
+	for _, e := range entries {
+		if _, err := exec.ExecContext(ctx, insertEntry, e.Account, string(e.Direction), e.Amount, e.Reference); err != nil {
+			return fmt.Errorf("ledger: gagal menulis entry: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Balance menghitung saldo sebuah akun dengan menjumlahkan seluruh entry
+// yang tercatat (debit dikurangi kredit), bukan dengan membaca kolom balance
+// yang di-mutate.
+func Balance(ctx context.Context, db *sql.DB, account string) (float64, error) {
+	const query = `
+		SELECT
+			COALESCE(SUM(CASE WHEN direction = 'debit' THEN amount ELSE 0 END), 0) -
+			COALESCE(SUM(CASE WHEN direction = 'credit' THEN amount ELSE 0 END), 0)
+		FROM ledger_entry
+		WHERE account = $1
+	`
+	var balance float64
+	if err := db.QueryRowContext(ctx, query, account).Scan(&balance); err != nil {
+		return 0, fmt.Errorf("ledger: gagal menghitung balance %q: %w", account, err)
+	}
+	return balance, nil
+}
+
+// AccountFromID menerjemahkan segmen path `/accounts/{id}` menjadi nama akun
+// ledger. ID numerik dianggap sebagai employee ID (akun payable-nya),
+// sedangkan "company" merujuk ke akun cash perusahaan.
+func AccountFromID(id string) (string, error) {
+	if id == "company" {
+		return AccountCompanyCash, nil
+	}
+	employeeID, err := strconv.Atoi(id)
+	if err != nil {
+		return "", fmt.Errorf("ledger: account id %q tidak valid", id)
+	}
+	return EmployeePayableAccount(employeeID), nil
+}
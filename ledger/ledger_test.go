@@ -0,0 +1,83 @@
+package ledger
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+)
+
+// fakeExec mencatat setiap ExecContext yang masuk tanpa menyentuh database
+// sungguhan, supaya invariant PostTransaction bisa diuji murni.
+type fakeExec struct {
+	queries []string
+	args    [][]any
+}
+
+func (f *fakeExec) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	f.queries = append(f.queries, query)
+	f.args = append(f.args, args)
+	return nil, nil
+}
+
+func TestPostTransaction_RejectsUnbalancedPosting(t *testing.T) {
+	exec := &fakeExec{}
+	err := PostTransaction(context.Background(), exec, []Entry{
+		{Account: AccountCompanyCash, Direction: Debit, Amount: 100, Reference: "test"},
+		{Account: AccountTopUpSource, Direction: Credit, Amount: 50, Reference: "test"},
+	})
+	if err == nil {
+		t.Fatal("expected unbalanced posting to be rejected")
+	}
+	if len(exec.queries) != 0 {
+		t.Fatalf("expected no entry to be written, got %d", len(exec.queries))
+	}
+}
+
+func TestPostTransaction_RejectsEmptyPosting(t *testing.T) {
+	exec := &fakeExec{}
+	if err := PostTransaction(context.Background(), exec, nil); err == nil {
+		t.Fatal("expected posting without entries to be rejected")
+	}
+}
+
+func TestPostTransaction_RejectsUnknownDirection(t *testing.T) {
+	exec := &fakeExec{}
+	err := PostTransaction(context.Background(), exec, []Entry{
+		{Account: AccountCompanyCash, Direction: "invalid", Amount: 100, Reference: "test"},
+	})
+	if err == nil {
+		t.Fatal("expected unknown direction to be rejected")
+	}
+}
+
+func TestPostTransaction_WritesBalancedPosting(t *testing.T) {
+	exec := &fakeExec{}
+	err := PostTransaction(context.Background(), exec, []Entry{
+		{Account: AccountCompanyCash, Direction: Debit, Amount: 100, Reference: "top_up"},
+		{Account: AccountTopUpSource, Direction: Credit, Amount: 100, Reference: "top_up"},
+	})
+	if err != nil {
+		t.Fatalf("expected balanced posting to succeed, got %v", err)
+	}
+	if len(exec.queries) != 2 {
+		t.Fatalf("expected 2 entries written, got %d", len(exec.queries))
+	}
+}
+
+func TestEmployeePayableAccount(t *testing.T) {
+	if got, want := EmployeePayableAccount(42), "employee_payable:42"; got != want {
+		t.Fatalf("EmployeePayableAccount(42) = %q, want %q", got, want)
+	}
+}
+
+func TestAccountFromID(t *testing.T) {
+	if got, err := AccountFromID("company"); err != nil || got != AccountCompanyCash {
+		t.Fatalf("AccountFromID(company) = (%q, %v), want (%q, nil)", got, err, AccountCompanyCash)
+	}
+	if got, err := AccountFromID("7"); err != nil || got != EmployeePayableAccount(7) {
+		t.Fatalf("AccountFromID(7) = (%q, %v), want (%q, nil)", got, err, EmployeePayableAccount(7))
+	}
+	if _, err := AccountFromID("not-an-id"); err == nil {
+		t.Fatal("expected invalid account id to be rejected")
+	}
+}
@@ -1,242 +1,413 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
-	"time"
+	"strconv"
 
 	"github.com/gorilla/mux"
 	_ "github.com/lib/pq"
+
+	"github.com/Lionel-nmJV/Self-Payroll-Web-Service/auth"
+	"github.com/Lionel-nmJV/Self-Payroll-Web-Service/config"
+	"github.com/Lionel-nmJV/Self-Payroll-Web-Service/payroll"
+	"github.com/Lionel-nmJV/Self-Payroll-Web-Service/service"
+	"github.com/Lionel-nmJV/Self-Payroll-Web-Service/webhook"
 )
 
-// Struct untuk menyimpan informasi top up balance perusahaan
-type TopUp struct {
-	ID          int       `json:"id"`
-	Amount      float64   `json:"amount"`
-	Transaction time.Time `json:"transaction"`
+// Struct untuk menyimpan respons API
+type APIResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
 }
 
-// Struct untuk menyimpan informasi pengurangan balance perusahaan
-type Deduction struct {
-	ID          int       `json:"id"`
-	Amount      float64   `json:"amount"`
-	Transaction time.Time `json:"transaction"`
+// App menyimpan dependency yang dibutuhkan seluruh handler HTTP. Handler
+// menjadi method App alih-alih fungsi bebas yang menutup (closure) atas
+// variabel package-level, supaya tidak ada handler yang bisa diam-diam
+// mengakses *sql.DB yang berbeda dari yang benar-benar dipakai main().
+type App struct {
+	svc *service.Service
+	cfg *config.Config
 }
 
-// Struct untuk menyimpan informasi jabatan
-type Position struct {
-	ID     int     `json:"id"`
-	Name   string  `json:"name"`
-	Salary float64 `json:"salary"`
+// NewApp membuat App dari service layer dan konfigurasi yang sudah di-load.
+func NewApp(svc *service.Service, cfg *config.Config) *App {
+	return &App{svc: svc, cfg: cfg}
 }
 
-// Struct untuk menyimpan informasi employee
-type Employee struct {
-	ID        int       `json:"id"`
-	Name      string    `json:"name"`
-	Position  Position  `json:"position"`
-	SecretID  string    `json:"-"`
-	Withdrawn bool      `json:"withdrawn"`
-	LastMonth time.Time `json:"last_month"`
+// withAuth membungkus sebuah handler dengan auth.Middleware (memverifikasi
+// JWT) dan auth.RequireRole (memverifikasi role), dalam urutan itu.
+func (a *App) withAuth(handler http.HandlerFunc, role string) http.Handler {
+	return auth.Middleware(a.cfg.JWTSecret)(auth.RequireRole(role)(handler))
 }
 
-// Struct untuk menyimpan respons API
-type APIResponse struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
+// withAnyAuth membungkus sebuah handler dengan auth.Middleware saja, tanpa
+// membatasi role tertentu.
+func (a *App) withAnyAuth(handler http.HandlerFunc) http.Handler {
+	return auth.Middleware(a.cfg.JWTSecret)(handler)
 }
 
-var (
-	db *sql.DB
-)
+// writeServiceError memetakan sentinel error dari package service menjadi
+// status code dan pesan HTTP yang sesuai.
+func writeServiceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrEmployeeNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Employee not found"})
+	case errors.Is(err, service.ErrPositionNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Position not found"})
+	case errors.Is(err, service.ErrForbidden):
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Forbidden"})
+	case errors.Is(err, service.ErrAlreadyDisbursed):
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Salary already withdrawn this month"})
+	case errors.Is(err, service.ErrInsufficientBalance):
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Insufficient company balance"})
+	case errors.Is(err, service.ErrUnknownAction):
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Unknown action"})
+	case errors.Is(err, service.ErrInvalidCredential):
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Invalid credentials"})
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Internal error"})
+	}
+}
 
 // Handler untuk melakukan top up balance perusahaan
-func TopUpBalanceHandler(w http.ResponseWriter, r *http.Request) {
-	// Mendapatkan nilai amount dari body request
-	var topUp TopUp
-	err := json.NewDecoder(r.Body).Decode(&topUp)
-	if err != nil {
+func (a *App) TopUpBalanceHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Amount float64 `json:"amount"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Invalid request"})
+		return
+	}
+
+	if err := a.svc.TopUp(r.Context(), req.Amount); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(APIResponse{Success: true, Message: "Balance topped up successfully"})
+}
+
+// Handler untuk melakukan pengurangan balance perusahaan
+func (a *App) DeductBalanceHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Amount float64 `json:"amount"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Invalid request"})
 		return
 	}
 
-	// Simpan data top up ke database
-	insertTopUp := `
-		INSERT INTO top_up (amount, transaction)
-		VALUES ($1, $2)
-	`
-	_, err = db.Exec(insertTopUp, topUp.Amount, time.Now())
+	if err := a.svc.Deduct(r.Context(), req.Amount); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(APIResponse{Success: true, Message: "Balance deducted successfully"})
+}
+
+// Handler untuk melakukan penarikan salary oleh employee
+func (a *App) WithdrawSalaryHandler(w http.ResponseWriter, r *http.Request) {
+	// Principal sudah diverifikasi oleh auth.Middleware; employee hanya boleh
+	// menarik salary miliknya sendiri
+	principal, _ := auth.FromContext(r.Context())
+
+	employeeID, err := strconv.Atoi(r.FormValue("employee_id"))
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Failed to top up balance"})
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Invalid employee_id"})
 		return
 	}
 
-	// Tambahkan balance perusahaan
-	updateBalance := `
-		UPDATE company
-		SET balance = balance + $1
-	`
-	_, err = db.Exec(updateBalance, topUp.Amount)
+	if err := a.svc.WithdrawSalary(r.Context(), *principal, employeeID); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(APIResponse{Success: true, Message: "Salary withdrawn successfully"})
+}
+
+// Handler untuk memulai sebuah payroll run batch untuk seluruh employee
+func (a *App) PayrollRunHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Period string `json:"period"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Period == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Invalid request"})
+		return
+	}
+
+	run, disbursements, err := a.svc.RunPayroll(r.Context(), req.Period)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Failed to update balance"})
+		writeServiceError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(APIResponse{Success: true, Message: "Balance topped up successfully"})
+	json.NewEncoder(w).Encode(struct {
+		Run           *payroll.Run           `json:"run"`
+		Disbursements []payroll.Disbursement `json:"disbursements"`
+	}{Run: run, Disbursements: disbursements})
 }
 
-// Handler untuk melakukan pengurangan balance perusahaan
-func DeductBalanceHandler(w http.ResponseWriter, r *http.Request) {
-	// Mendapatkan nilai amount dari body request
-	var deduction Deduction
-	err := json.NewDecoder(r.Body).Decode(&deduction)
+// Handler untuk melihat detail sebuah payroll run beserta employee yang
+// berhasil dibayar dalam run tersebut; employee yang gagal tidak tercantum
+func (a *App) GetPayrollRunHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	runID, err := strconv.Atoi(id)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Invalid request"})
+		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Invalid run id"})
 		return
 	}
 
-	// Simpan data pengurangan balance ke database
-	insertDeduction := `
-		INSERT INTO deduction (amount, transaction)
-		VALUES ($1, $2)
-	`
-	_, err = db.Exec(insertDeduction, deduction.Amount, time.Now())
+	run, disbursements, err := a.svc.GetPayrollRun(r.Context(), runID)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Failed to deduct balance"})
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Payroll run not found"})
 		return
 	}
 
-	// Kurangi balance perusahaan
-	updateBalance := `
-		UPDATE company
-		SET balance = balance - $1
-	`
-	_, err = db.Exec(updateBalance, deduction.Amount)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(struct {
+		Run           *payroll.Run           `json:"run"`
+		Disbursements []payroll.Disbursement `json:"disbursements"`
+	}{Run: run, Disbursements: disbursements})
+}
+
+// Handler untuk menghitung balance sebuah akun dari entry ledger
+func (a *App) AccountBalanceHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	account, balance, err := a.svc.AccountBalance(r.Context(), id)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Failed to update balance"})
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Invalid account id"})
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(APIResponse{Success: true, Message: "Balance deducted successfully"})
+	json.NewEncoder(w).Encode(struct {
+		Account string  `json:"account"`
+		Balance float64 `json:"balance"`
+	}{Account: account, Balance: balance})
 }
 
-// Handler untuk melakukan penarikan salary oleh employee
-func WithdrawSalaryHandler(w http.ResponseWriter, r *http.Request) {
-	// Mendapatkan nilai employee ID dan secret ID dari query parameters
-	employeeID := r.FormValue("employee_id")
-	secretID := r.FormValue("secret_id")
-
-	// Periksa apakah employee dengan ID yang diberikan ada
-	queryEmployee := `
-		SELECT e.id, e.name, e.secret_id, e.withdrawn, e.last_month, p.id, p.name, p.salary
-		FROM employee AS e
-		INNER JOIN position AS p ON e.position_id = p.id
-		WHERE e.id = $1
-	`
-	row := db.QueryRow(queryEmployee, employeeID)
-	var employee Employee
-	err := row.Scan(
-		&employee.ID,
-		&employee.Name,
-		&employee.SecretID,
-		&employee.Withdrawn,
-		&employee.LastMonth,
-		&employee.Position.ID,
-		&employee.Position.Name,
-		&employee.Position.Salary,
-	)
+// Handler untuk login dan menerbitkan JWT. Role "employee" diautentikasi
+// dengan employee_id+password terhadap employee.password_hash, role "admin"
+// dengan username+password terhadap admin_user.password_hash.
+func (a *App) AuthLoginHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Role       string `json:"role"`
+		EmployeeID int    `json:"employee_id"`
+		Username   string `json:"username"`
+		Password   string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Invalid request"})
+		return
+	}
+
+	token, err := a.svc.Login(r.Context(), a.cfg.JWTSecret, req.Role, req.EmployeeID, req.Username, req.Password)
 	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Employee not found"})
+		writeServiceError(w, err)
 		return
 	}
 
-	// Periksa apakah secret ID yang diberikan cocok dengan secret ID employee
-	if secretID != employee.SecretID {
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(struct {
+		Token string `json:"token"`
+	}{Token: token})
+}
+
+// Handler untuk memeriksa status login dari sebuah token (introspection)
+func (a *App) CheckLoginStatusHandler(w http.ResponseWriter, r *http.Request) {
+	principal, ok := auth.FromContext(r.Context())
+	if !ok {
 		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Unauthorized"})
+		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Not authenticated"})
 		return
 	}
 
-	// Periksa apakah employee sudah melakukan penarikan pada bulan ini
-	currentMonth := time.Now().Month()
-	if employee.LastMonth.Month() == currentMonth && employee.Withdrawn {
-		w.WriteHeader(http.StatusForbidden)
-		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Salary already withdrawn this month"})
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(struct {
+		ID   int    `json:"id"`
+		Role string `json:"role"`
+	}{ID: principal.ID, Role: principal.Role})
+}
+
+// Struct untuk menyimpan request pengelolaan jabatan
+type ManagePositionRequest struct {
+	Action string  `json:"action"`
+	ID     int     `json:"id"`
+	Name   string  `json:"name"`
+	Salary float64 `json:"salary"`
+}
+
+// Handler untuk mengelola informasi jabatan (add/edit/delete)
+func (a *App) ManagePositionHandler(w http.ResponseWriter, r *http.Request) {
+	var req ManagePositionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Invalid request"})
 		return
 	}
 
-	// Kurangi balance perusahaan sesuai dengan besaran salary employee
-	updateBalance := `
-		UPDATE company
-		SET balance = balance - $1
-	`
-	_, err = db.Exec(updateBalance, employee.Position.Salary)
+	id, err := a.svc.ManagePosition(r.Context(), req.Action, req.ID, req.Name, req.Salary)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Failed to update balance"})
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(struct {
+		APIResponse
+		ID int `json:"id"`
+	}{APIResponse{Success: true, Message: "Position managed successfully"}, id})
+}
+
+// Struct untuk menyimpan request pengelolaan employee
+type ManageEmployeeRequest struct {
+	Action     string `json:"action"`
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	PositionID int    `json:"position_id"`
+	Password   string `json:"password"`
+}
+
+// Handler untuk mengelola informasi employee (add/edit/delete)
+func (a *App) ManageEmployeeHandler(w http.ResponseWriter, r *http.Request) {
+	var req ManageEmployeeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Invalid request"})
 		return
 	}
 
-	// Update status penarikan salary employee
-	updateEmployee := `
-		UPDATE employee
-		SET withdrawn = true, last_month = $1
-		WHERE id = $2
-	`
-	_, err = db.Exec(updateEmployee, time.Now(), employee.ID)
+	id, err := a.svc.ManageEmployee(r.Context(), req.Action, req.ID, req.Name, req.PositionID, req.Password)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Failed to update employee status"})
+		writeServiceError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(APIResponse{Success: true, Message: "Salary withdrawn successfully"})
+	json.NewEncoder(w).Encode(struct {
+		APIResponse
+		ID int `json:"id"`
+	}{APIResponse{Success: true, Message: "Employee managed successfully"}, id})
 }
 
-// Handler untuk mengelola informasi jabatan
-func ManagePositionHandler(w http.ResponseWriter, r *http.Request) {
-	// Tambahkan kode untuk mengelola informasi jabatan
+// Handler untuk mendaftarkan subscriber webhook baru
+func (a *App) WebhookSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		URL         string `json:"url"`
+		Secret      string `json:"secret"`
+		EventFilter string `json:"event_filter"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.URL == "" || req.Secret == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Invalid request"})
+		return
+	}
+
+	sub, err := a.svc.Subscribe(r.Context(), req.URL, req.Secret, req.EventFilter)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Failed to register subscription"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(sub)
 }
 
-// Handler untuk mengelola informasi employee
-func ManageEmployeeHandler(w http.ResponseWriter, r *http.Request) {
-	// Tambahkan kode untuk mengelola informasi employee
+// Handler untuk memeriksa status pengiriman webhook
+func (a *App) WebhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	deliveries, err := a.svc.ListDeliveries(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Failed to list deliveries"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(deliveries)
 }
 
 // Fungsi utama
 func main() {
+	// Memuat konfigurasi dari environment variable, dengan fallback ke
+	// conf/app.conf untuk key yang belum di-set. Gagal cepat jika ada key
+	// wajib yang tidak ditemukan di kedua sumber tersebut.
+	cfg, err := config.Load("conf/app.conf")
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	// Koneksi ke database PostgreSQL
-	connStr := "user=<postgres> password=<2804> dbname=<postgres> sslmode=disable"
-	db, err := sql.Open("postgres", connStr)
+	db, err := sql.Open("postgres", cfg.ConnString())
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer db.Close()
 
+	svc := service.New(db)
+	app := NewApp(svc, cfg)
+
 	// Membuat router menggunakan package gorilla/mux
 	router := mux.NewRouter()
 
-	// Mengatur route dan handler untuk setiap fitur
-	router.HandleFunc("/topup", TopUpBalanceHandler).Methods("POST")
-	router.HandleFunc("/deduct", DeductBalanceHandler).Methods("POST")
-	router.HandleFunc("/withdraw", WithdrawSalaryHandler).Methods("POST")
-	router.HandleFunc("/position", ManagePositionHandler).Methods("POST")
-	router.HandleFunc("/employee", ManageEmployeeHandler).Methods("POST")
+	// Mengatur route dan handler untuk setiap fitur. /auth/login tidak
+	// memerlukan autentikasi; /auth/status dan /accounts/{id}/balance
+	// dibungkus dengan withAnyAuth (auth.Middleware saja, role apa pun);
+	// rute admin dan employee lainnya dibungkus dengan withAuth supaya
+	// diverifikasi oleh auth.Middleware + RequireRole.
+	router.HandleFunc("/auth/login", app.AuthLoginHandler).Methods("POST")
+	router.Handle("/auth/status", app.withAnyAuth(app.CheckLoginStatusHandler)).Methods("GET")
+	router.Handle("/topup", app.withAuth(app.TopUpBalanceHandler, auth.RoleAdmin)).Methods("POST")
+	router.Handle("/deduct", app.withAuth(app.DeductBalanceHandler, auth.RoleAdmin)).Methods("POST")
+	router.Handle("/withdraw", app.withAuth(app.WithdrawSalaryHandler, auth.RoleEmployee)).Methods("POST")
+	router.Handle("/position", app.withAuth(app.ManagePositionHandler, auth.RoleAdmin)).Methods("POST")
+	router.Handle("/employee", app.withAuth(app.ManageEmployeeHandler, auth.RoleAdmin)).Methods("POST")
+	router.Handle("/accounts/{id}/balance", app.withAnyAuth(app.AccountBalanceHandler)).Methods("GET")
+	router.Handle("/payroll/runs", app.withAuth(app.PayrollRunHandler, auth.RoleAdmin)).Methods("POST")
+	router.Handle("/payroll/runs/{id}", app.withAuth(app.GetPayrollRunHandler, auth.RoleAdmin)).Methods("GET")
+	router.Handle("/webhooks/subscriptions", app.withAuth(app.WebhookSubscriptionHandler, auth.RoleAdmin)).Methods("POST")
+	router.Handle("/webhooks/deliveries", app.withAuth(app.WebhookDeliveriesHandler, auth.RoleAdmin)).Methods("GET")
+
+	// Menjalankan scheduler yang memicu payroll run otomatis tiap tanggal 1
+	payroll.StartScheduler(context.Background(), db)
+
+	// Menjalankan worker pengiriman webhook ke subscriber terdaftar
+	webhook.StartDispatcher(context.Background(), db)
+
+	// gRPC + gRPC-gateway (proto/payroll.proto, grpcserver package) belum
+	// diaktifkan di sini: payrollpb (hasil `make proto`) belum pernah
+	// di-generate atau dicommit, jadi grpcserver tidak bisa dicompile.
+	// Setelah payrollpb digenerate lewat `make proto` pada mesin dengan
+	// protoc terpasang, pasang kembali grpcserver.ListenAndServe di sini.
 
-	// Menjalankan server pada port tertentu
-	fmt.Println("Server started on port 8080")
-	log.Fatal(http.ListenAndServe(":8080", router))
+	// Menjalankan server pada port yang diatur lewat HTTP_PORT
+	fmt.Printf("Server started on port %s (mode=%s)\n", cfg.HTTPPort, cfg.RunMode)
+	log.Fatal(http.ListenAndServe(cfg.Addr(), router))
 }
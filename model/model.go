@@ -0,0 +1,34 @@
+// Package model menyimpan struct domain yang dipakai oleh transport HTTP
+// lewat package service.
+package model
+
+import "time"
+
+// TopUp menyimpan informasi top up balance perusahaan.
+type TopUp struct {
+	ID          int       `json:"id"`
+	Amount      float64   `json:"amount"`
+	Transaction time.Time `json:"transaction"`
+}
+
+// Deduction menyimpan informasi pengurangan balance perusahaan.
+type Deduction struct {
+	ID          int       `json:"id"`
+	Amount      float64   `json:"amount"`
+	Transaction time.Time `json:"transaction"`
+}
+
+// Position menyimpan informasi jabatan.
+type Position struct {
+	ID     int     `json:"id"`
+	Name   string  `json:"name"`
+	Salary float64 `json:"salary"`
+}
+
+// Employee menyimpan informasi employee.
+type Employee struct {
+	ID           int      `json:"id"`
+	Name         string   `json:"name"`
+	Position     Position `json:"position"`
+	PasswordHash string   `json:"-"`
+}
@@ -0,0 +1,279 @@
+// Package payroll menyediakan batch payroll run bulanan yang menggantikan
+// alur penarikan salary per-employee yang ad-hoc. Idempotency tidak lagi
+// bersandar pada kolom `withdrawn`/`last_month` di tabel employee (yang bisa
+// salah di sekitar pergantian tahun karena hanya membandingkan Month()),
+// melainkan pada constraint UNIQUE(employee_id, period) di tabel
+// payroll_disbursement.
+package payroll
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Lionel-nmJV/Self-Payroll-Web-Service/ledger"
+)
+
+// Status yang mungkin dimiliki sebuah Run.
+const (
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+)
+
+// Run merepresentasikan satu payroll_run untuk sebuah period ("2006-01").
+type Run struct {
+	ID        int       `json:"id"`
+	Period    string    `json:"period"`
+	Status    string    `json:"status"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// Disbursement adalah hasil payroll untuk satu employee dalam satu Run.
+type Disbursement struct {
+	EmployeeID int    `json:"employee_id"`
+	Success    bool   `json:"success"`
+	Message    string `json:"message"`
+}
+
+// CurrentPeriod mengembalikan period berjalan dalam format "2006-01".
+func CurrentPeriod() string {
+	return time.Now().Format("2006-01")
+}
+
+// AlreadyDisbursed memeriksa apakah employee sudah menerima salary pada
+// period tertentu, dengan membaca payroll_disbursement alih-alih kolom
+// withdrawn/last_month di tabel employee.
+func AlreadyDisbursed(ctx context.Context, db *sql.DB, employeeID int, period string) (bool, error) {
+	const query = `SELECT EXISTS(SELECT 1 FROM payroll_disbursement WHERE employee_id = $1 AND period = $2)`
+	var exists bool
+	if err := db.QueryRowContext(ctx, query, employeeID, period).Scan(&exists); err != nil {
+		return false, fmt.Errorf("payroll: gagal memeriksa disbursement: %w", err)
+	}
+	return exists, nil
+}
+
+// execer disatukan oleh *sql.DB dan *sql.Tx, sehingga ClaimDisbursement bisa
+// dipanggil sebagai bagian dari transaksi milik pemanggil (lihat pola yang
+// sama di ledger.PostTransaction/webhook.Emit).
+type execer interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// ClaimDisbursement mencoba mengklaim slot UNIQUE(employee_id, period) di
+// payroll_disbursement secara atomic lewat INSERT ... WHERE NOT EXISTS,
+// bukan check-then-insert yang bisa race di antara dua percobaan disbursement
+// konkuren untuk employee/period yang sama. runID 0 berarti disbursement
+// ad-hoc (di luar payroll run manapun) dan disimpan dengan run_id NULL.
+// Mengembalikan claimed=false (tanpa error) kalau slot tersebut sudah
+// diklaim sebelumnya.
+func ClaimDisbursement(ctx context.Context, exec execer, employeeID int, period string, runID int) (claimed bool, err error) {
+	var row *sql.Row
+	if runID != 0 {
+		const insert = `
+			INSERT INTO payroll_disbursement (employee_id, period, run_id)
+			SELECT $1, $2, $3
+			WHERE NOT EXISTS (
+				SELECT 1 FROM payroll_disbursement WHERE employee_id = $1 AND period = $2
+			)
+			RETURNING id
+		`
+		row = exec.QueryRowContext(ctx, insert, employeeID, period, runID)
+	} else {
+		const insert = `
+			INSERT INTO payroll_disbursement (employee_id, period)
+			SELECT $1, $2
+			WHERE NOT EXISTS (
+				SELECT 1 FROM payroll_disbursement WHERE employee_id = $1 AND period = $2
+			)
+			RETURNING id
+		`
+		row = exec.QueryRowContext(ctx, insert, employeeID, period)
+	}
+
+	var disbursementID int
+	if err := row.Scan(&disbursementID); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("payroll: gagal mengklaim disbursement: %w", err)
+	}
+	return true, nil
+}
+
+// StartRun membuat record payroll_run baru berstatus running.
+func StartRun(ctx context.Context, db *sql.DB, period string) (*Run, error) {
+	const insert = `
+		INSERT INTO payroll_run (period, status, started_at)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`
+	run := &Run{Period: period, Status: StatusRunning, StartedAt: time.Now()}
+	if err := db.QueryRowContext(ctx, insert, period, run.Status, run.StartedAt).Scan(&run.ID); err != nil {
+		return nil, fmt.Errorf("payroll: gagal membuat payroll run: %w", err)
+	}
+	return run, nil
+}
+
+// completeRun menandai payroll_run sebagai selesai.
+func completeRun(ctx context.Context, db *sql.DB, runID int) error {
+	const update = `UPDATE payroll_run SET status = $1, completed_at = $2 WHERE id = $3`
+	_, err := db.ExecContext(ctx, update, StatusCompleted, time.Now(), runID)
+	return err
+}
+
+// disburseEmployee memproses satu employee untuk satu Run: insert idempotent
+// ke payroll_disbursement (ditolak diam-diam jika employee ini sudah dibayar
+// pada period tersebut) dan posting ledger-nya dilakukan dalam satu
+// transaksi yang sama, supaya insert yang mengklaim slot
+// UNIQUE(employee_id, period) tidak pernah commit tanpa ledger entry yang
+// menyertainya — kalau posting gagal, seluruh tx (termasuk insert-nya)
+// di-rollback dan employee tetap berstatus belum dibayar untuk period ini.
+func disburseEmployee(ctx context.Context, db *sql.DB, runID int, period string, employeeID int, salary float64) Disbursement {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return Disbursement{EmployeeID: employeeID, Success: false, Message: fmt.Sprintf("failed to open transaction: %v", err)}
+	}
+	defer tx.Rollback()
+
+	claimed, err := ClaimDisbursement(ctx, tx, employeeID, period, runID)
+	if err != nil {
+		return Disbursement{EmployeeID: employeeID, Success: false, Message: fmt.Sprintf("failed to record disbursement: %v", err)}
+	}
+	if !claimed {
+		return Disbursement{EmployeeID: employeeID, Success: false, Message: "already disbursed for this period"}
+	}
+
+	balance, err := ledger.Balance(ctx, db, ledger.AccountCompanyCash)
+	if err != nil {
+		return Disbursement{EmployeeID: employeeID, Success: false, Message: fmt.Sprintf("failed to read company balance: %v", err)}
+	}
+	if balance < salary {
+		return Disbursement{EmployeeID: employeeID, Success: false, Message: "insufficient company balance"}
+	}
+
+	reference := fmt.Sprintf("payroll_run=%d;employee=%d", runID, employeeID)
+	if err := ledger.PostTransaction(ctx, tx, []ledger.Entry{
+		{Account: ledger.EmployeePayableAccount(employeeID), Direction: ledger.Debit, Amount: salary, Reference: reference},
+		{Account: ledger.AccountCompanyCash, Direction: ledger.Credit, Amount: salary, Reference: reference},
+	}); err != nil {
+		return Disbursement{EmployeeID: employeeID, Success: false, Message: fmt.Sprintf("failed to post ledger entries: %v", err)}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Disbursement{EmployeeID: employeeID, Success: false, Message: fmt.Sprintf("failed to commit disbursement: %v", err)}
+	}
+
+	return Disbursement{EmployeeID: employeeID, Success: true, Message: "disbursed"}
+}
+
+// employeeSalary merepresentasikan baris employee+position yang dibutuhkan
+// untuk melakukan disbursement.
+type employeeSalary struct {
+	ID     int
+	Salary float64
+}
+
+// RunPayroll menjalankan payroll run untuk seluruh employee pada period
+// tertentu. Setiap employee diproses dalam transaksi ledger-nya sendiri,
+// sehingga kegagalan satu employee tidak menggagalkan employee lain.
+func RunPayroll(ctx context.Context, db *sql.DB, period string) (*Run, []Disbursement, error) {
+	run, err := StartRun(ctx, db, period)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT e.id, p.salary
+		FROM employee AS e
+		INNER JOIN position AS p ON e.position_id = p.id
+	`)
+	if err != nil {
+		return run, nil, fmt.Errorf("payroll: gagal membaca daftar employee: %w", err)
+	}
+	defer rows.Close()
+
+	var employees []employeeSalary
+	for rows.Next() {
+		var e employeeSalary
+		if err := rows.Scan(&e.ID, &e.Salary); err != nil {
+			return run, nil, fmt.Errorf("payroll: gagal membaca baris employee: %w", err)
+		}
+		employees = append(employees, e)
+	}
+
+	disbursements := make([]Disbursement, 0, len(employees))
+	for _, e := range employees {
+		disbursements = append(disbursements, disburseEmployee(ctx, db, run.ID, period, e.ID, e.Salary))
+	}
+
+	if err := completeRun(ctx, db, run.ID); err != nil {
+		return run, disbursements, fmt.Errorf("payroll: gagal menyelesaikan run: %w", err)
+	}
+	run.Status = StatusCompleted
+
+	return run, disbursements, nil
+}
+
+// GetRun mengembalikan payroll_run beserta disbursement yang tercatat di
+// payroll_disbursement untuk run tersebut, yaitu hanya employee yang
+// berhasil dibayar (Success selalu true, Message kosong). Employee yang
+// gagal dalam run tidak disimpan, jadi tidak ikut dikembalikan.
+func GetRun(ctx context.Context, db *sql.DB, runID int) (*Run, []Disbursement, error) {
+	run := &Run{ID: runID}
+	err := db.QueryRowContext(ctx, `SELECT period, status, started_at FROM payroll_run WHERE id = $1`, runID).
+		Scan(&run.Period, &run.Status, &run.StartedAt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("payroll: run %d tidak ditemukan: %w", runID, err)
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT employee_id FROM payroll_disbursement WHERE run_id = $1
+	`, runID)
+	if err != nil {
+		return run, nil, fmt.Errorf("payroll: gagal membaca disbursement run %d: %w", runID, err)
+	}
+	defer rows.Close()
+
+	var disbursements []Disbursement
+	for rows.Next() {
+		var d Disbursement
+		if err := rows.Scan(&d.EmployeeID); err != nil {
+			return run, nil, fmt.Errorf("payroll: gagal membaca baris disbursement: %w", err)
+		}
+		d.Success = true
+		disbursements = append(disbursements, d)
+	}
+
+	return run, disbursements, nil
+}
+
+// StartScheduler menjalankan goroutine yang memicu payroll run otomatis pada
+// tanggal 1 setiap bulan. Pengecekan dilakukan setiap jam agar tidak
+// tergantung pada proses yang hidup tepat di tengah malam pergantian bulan,
+// dan dijaga idempotent oleh UNIQUE(employee_id, period) di
+// payroll_disbursement sehingga run yang terpicu berkali-kali tetap aman.
+func StartScheduler(ctx context.Context, db *sql.DB) {
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+
+		lastTriggeredPeriod := ""
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				now := time.Now()
+				period := now.Format("2006-01")
+				if now.Day() != 1 || period == lastTriggeredPeriod {
+					continue
+				}
+				lastTriggeredPeriod = period
+				if _, _, err := RunPayroll(ctx, db, period); err != nil {
+					fmt.Printf("payroll: scheduled run for %s failed: %v\n", period, err)
+				}
+			}
+		}
+	}()
+}
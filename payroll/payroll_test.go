@@ -0,0 +1,108 @@
+package payroll
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	_ "github.com/lib/pq"
+)
+
+// testDB membuka koneksi ke Postgres lewat TEST_DATABASE_URL dan men-skip
+// test kalau env var itu tidak di-set atau DB-nya tidak bisa dihubungi.
+// payroll_disbursement mengandalkan constraint UNIQUE(employee_id, period)
+// milik Postgres sungguhan, jadi idempotency-nya tidak bisa diuji lewat
+// stub/mock tanpa mengimplementasikan ulang semantik constraint tersebut.
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set, skipping payroll integration test")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Skipf("failed to open TEST_DATABASE_URL: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.Ping(); err != nil {
+		t.Skipf("failed to reach TEST_DATABASE_URL: %v", err)
+	}
+
+	const schema = `
+		CREATE TABLE IF NOT EXISTS ledger_entry (
+			id SERIAL PRIMARY KEY,
+			account TEXT NOT NULL,
+			direction TEXT NOT NULL,
+			amount NUMERIC NOT NULL,
+			reference TEXT NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS payroll_run (
+			id SERIAL PRIMARY KEY,
+			period TEXT NOT NULL,
+			status TEXT NOT NULL,
+			started_at TIMESTAMPTZ NOT NULL,
+			completed_at TIMESTAMPTZ
+		);
+		CREATE TABLE IF NOT EXISTS payroll_disbursement (
+			id SERIAL PRIMARY KEY,
+			employee_id INTEGER NOT NULL,
+			period TEXT NOT NULL,
+			run_id INTEGER,
+			UNIQUE(employee_id, period)
+		);
+		TRUNCATE ledger_entry, payroll_run, payroll_disbursement;
+	`
+	if _, err := db.Exec(schema); err != nil {
+		t.Skipf("failed to prepare payroll schema: %v", err)
+	}
+
+	return db
+}
+
+func TestDisburseEmployee_RejectsDuplicateForSamePeriod(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	run, err := StartRun(ctx, db, "2026-07")
+	if err != nil {
+		t.Fatalf("StartRun: %v", err)
+	}
+
+	// Salary 0 supaya test tidak perlu menyetor company_cash lewat ledger
+	// hanya untuk melewati pengecekan saldo; yang diuji di sini adalah
+	// idempotency-nya, bukan pengecekan saldo.
+	first := disburseEmployee(ctx, db, run.ID, "2026-07", 1, 0)
+	if !first.Success {
+		t.Fatalf("expected first disbursement to succeed, got %q", first.Message)
+	}
+
+	second := disburseEmployee(ctx, db, run.ID, "2026-07", 1, 0)
+	if second.Success {
+		t.Fatal("expected second disbursement for the same employee/period to be rejected")
+	}
+
+	alreadyDisbursed, err := AlreadyDisbursed(ctx, db, 1, "2026-07")
+	if err != nil {
+		t.Fatalf("AlreadyDisbursed: %v", err)
+	}
+	if !alreadyDisbursed {
+		t.Fatal("expected employee to be marked as already disbursed for this period")
+	}
+
+	entryCount, err := ledgerEntryCountForTest(ctx, db)
+	if err != nil {
+		t.Fatalf("failed to read ledger entries: %v", err)
+	}
+	if entryCount != 2 {
+		t.Fatalf("expected exactly one disbursement's worth of ledger entries (2 rows), got %d", entryCount)
+	}
+}
+
+// ledgerEntryCountForTest menghitung jumlah baris ledger_entry, dipakai untuk
+// memastikan disbursement yang ditolak tidak meninggalkan entry ganda.
+func ledgerEntryCountForTest(ctx context.Context, db *sql.DB) (int, error) {
+	var count int
+	err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM ledger_entry`).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,378 @@
+// Package service berisi business logic yang sebelumnya tersebar di
+// masing-masing HTTP handler. Handler HTTP (mux) memanggil package ini;
+// tidak ada transport gRPC di tree ini yang memanggilnya.
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Lionel-nmJV/Self-Payroll-Web-Service/auth"
+	"github.com/Lionel-nmJV/Self-Payroll-Web-Service/ledger"
+	"github.com/Lionel-nmJV/Self-Payroll-Web-Service/model"
+	"github.com/Lionel-nmJV/Self-Payroll-Web-Service/payroll"
+	"github.com/Lionel-nmJV/Self-Payroll-Web-Service/webhook"
+)
+
+// Sentinel error yang dipetakan masing-masing transport ke representasi
+// error/status code miliknya sendiri (HTTP status, gRPC status code, dst).
+var (
+	ErrEmployeeNotFound    = errors.New("service: employee not found")
+	ErrPositionNotFound    = errors.New("service: position not found")
+	ErrForbidden           = errors.New("service: forbidden")
+	ErrAlreadyDisbursed    = errors.New("service: salary already withdrawn this month")
+	ErrUnknownAction       = errors.New("service: unknown action")
+	ErrInvalidCredential   = errors.New("service: invalid credentials")
+	ErrInsufficientBalance = errors.New("service: insufficient company balance")
+)
+
+// Service membungkus koneksi database dan menyediakan seluruh operasi
+// domain payroll.
+type Service struct {
+	DB *sql.DB
+}
+
+// New membuat Service baru di atas koneksi database yang sudah dibuka.
+func New(db *sql.DB) *Service {
+	return &Service{DB: db}
+}
+
+// TopUp menyimpan top up balance dan memposting ledger-nya dalam satu
+// transaksi yang sama dengan audit row dan outbox event, supaya kegagalan
+// posting ledger tidak bisa meninggalkan audit row/event yang sudah
+// ter-commit tanpa pasangannya.
+func (s *Service) TopUp(ctx context.Context, amount float64) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("service: gagal top up: %w", err)
+	}
+	defer tx.Rollback()
+
+	topUp := model.TopUp{Amount: amount, Transaction: time.Now()}
+	_, err = tx.ExecContext(ctx, `INSERT INTO top_up (amount, transaction) VALUES ($1, $2)`, topUp.Amount, topUp.Transaction)
+	if err != nil {
+		return fmt.Errorf("service: gagal top up: %w", err)
+	}
+
+	if err := ledger.PostTransaction(ctx, tx, []ledger.Entry{
+		{Account: ledger.AccountCompanyCash, Direction: ledger.Debit, Amount: amount, Reference: "top_up"},
+		{Account: ledger.AccountTopUpSource, Direction: ledger.Credit, Amount: amount, Reference: "top_up"},
+	}); err != nil {
+		return fmt.Errorf("service: gagal top up: %w", err)
+	}
+
+	if err := webhook.Emit(ctx, tx, webhook.Event{Module: webhook.ModuleBalance, Action: webhook.ActionTopUp, Data: topUp}); err != nil {
+		return fmt.Errorf("service: gagal top up: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("service: gagal top up: %w", err)
+	}
+
+	return nil
+}
+
+// Deduct menyimpan pengurangan balance dan memposting ledger-nya dalam satu
+// transaksi yang sama dengan audit row dan outbox event.
+func (s *Service) Deduct(ctx context.Context, amount float64) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("service: gagal deduct: %w", err)
+	}
+	defer tx.Rollback()
+
+	deduction := model.Deduction{Amount: amount, Transaction: time.Now()}
+	_, err = tx.ExecContext(ctx, `INSERT INTO deduction (amount, transaction) VALUES ($1, $2)`, deduction.Amount, deduction.Transaction)
+	if err != nil {
+		return fmt.Errorf("service: gagal deduct: %w", err)
+	}
+
+	if err := ledger.PostTransaction(ctx, tx, []ledger.Entry{
+		{Account: ledger.AccountDeductionSink, Direction: ledger.Debit, Amount: amount, Reference: "deduction"},
+		{Account: ledger.AccountCompanyCash, Direction: ledger.Credit, Amount: amount, Reference: "deduction"},
+	}); err != nil {
+		return fmt.Errorf("service: gagal deduct: %w", err)
+	}
+
+	if err := webhook.Emit(ctx, tx, webhook.Event{Module: webhook.ModuleBalance, Action: webhook.ActionDeduct, Data: deduction}); err != nil {
+		return fmt.Errorf("service: gagal deduct: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("service: gagal deduct: %w", err)
+	}
+
+	return nil
+}
+
+// WithdrawSalary menarik salary employee untuk period berjalan, hanya boleh
+// dipanggil oleh principal yang merupakan employee itu sendiri.
+func (s *Service) WithdrawSalary(ctx context.Context, principal auth.Principal, employeeID int) error {
+	employee, err := s.employeeByID(ctx, employeeID)
+	if err != nil {
+		return err
+	}
+
+	if principal.ID != employee.ID {
+		return ErrForbidden
+	}
+
+	period := payroll.CurrentPeriod()
+
+	// Cek saldo company_cash sebelum memposting, sama seperti
+	// payroll.disburseEmployee, supaya invariant double-entry tidak bisa
+	// dilewati dengan memanggil endpoint ad-hoc ini alih-alih payroll run.
+	balance, err := ledger.Balance(ctx, s.DB, ledger.AccountCompanyCash)
+	if err != nil {
+		return fmt.Errorf("service: gagal memeriksa saldo perusahaan: %w", err)
+	}
+	if balance < employee.Position.Salary {
+		return ErrInsufficientBalance
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("service: gagal mencatat disbursement: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Mengklaim slot UNIQUE(employee_id, period) lewat idiom idempotent-insert
+	// yang sama dengan payroll.disburseEmployee (bukan check-then-insert
+	// terpisah), supaya dua panggilan withdraw konkuren untuk employee/period
+	// yang sama tidak bisa lolos keduanya dan hanya satu yang mengklaim slot.
+	claimed, err := payroll.ClaimDisbursement(ctx, tx, employee.ID, period, 0)
+	if err != nil {
+		return fmt.Errorf("service: gagal mencatat disbursement: %w", err)
+	}
+	if !claimed {
+		return ErrAlreadyDisbursed
+	}
+
+	// Posting ledger dalam tx yang sama dengan insert disbursement di atas:
+	// kalau posting gagal, UNIQUE(employee_id, period) yang baru saja
+	// diklaim ikut di-rollback, sehingga employee tidak pernah tercatat
+	// "sudah dibayar" tanpa ledger entry yang menyertainya.
+	reference := fmt.Sprintf("withdraw:employee=%d", employee.ID)
+	if err := ledger.PostTransaction(ctx, tx, []ledger.Entry{
+		{Account: ledger.EmployeePayableAccount(employee.ID), Direction: ledger.Debit, Amount: employee.Position.Salary, Reference: reference},
+		{Account: ledger.AccountCompanyCash, Direction: ledger.Credit, Amount: employee.Position.Salary, Reference: reference},
+	}); err != nil {
+		return fmt.Errorf("service: gagal mencatat disbursement: %w", err)
+	}
+
+	if err := webhook.Emit(ctx, tx, webhook.Event{Module: webhook.ModuleBalance, Action: webhook.ActionWithdraw, Data: employee}); err != nil {
+		return fmt.Errorf("service: gagal mencatat disbursement: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("service: gagal mencatat disbursement: %w", err)
+	}
+
+	return nil
+}
+
+// requireRowAffected mengembalikan notFound kalau result tidak menyentuh
+// satu baris pun, supaya edit/delete pada id yang tidak ada tidak diam-diam
+// melaporkan sukses dan menyiarkan event lifecycle untuk entity yang
+// sebenarnya tidak pernah ada.
+func requireRowAffected(result sql.Result, notFound error) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("service: gagal memeriksa rows affected: %w", err)
+	}
+	if rows == 0 {
+		return notFound
+	}
+	return nil
+}
+
+func (s *Service) employeeByID(ctx context.Context, id int) (model.Employee, error) {
+	const query = `
+		SELECT e.id, e.name, e.password_hash, p.id, p.name, p.salary
+		FROM employee AS e
+		INNER JOIN position AS p ON e.position_id = p.id
+		WHERE e.id = $1
+	`
+	var employee model.Employee
+	err := s.DB.QueryRowContext(ctx, query, id).Scan(
+		&employee.ID,
+		&employee.Name,
+		&employee.PasswordHash,
+		&employee.Position.ID,
+		&employee.Position.Name,
+		&employee.Position.Salary,
+	)
+	if err != nil {
+		return model.Employee{}, ErrEmployeeNotFound
+	}
+	return employee, nil
+}
+
+// EmployeeInfo mengembalikan informasi employee (tanpa credential) untuk
+// konsumsi read-only. Tidak ada route HTTP maupun server gRPC yang
+// memanggilnya.
+func (s *Service) EmployeeInfo(ctx context.Context, id int) (model.Employee, error) {
+	return s.employeeByID(ctx, id)
+}
+
+// ManagePosition melakukan add/edit/delete pada tabel position dan
+// menyiarkan event lifecycle-nya.
+func (s *Service) ManagePosition(ctx context.Context, action string, id int, name string, salary float64) (int, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("service: gagal mengelola position: %w", err)
+	}
+	defer tx.Rollback()
+
+	switch action {
+	case webhook.ActionAdd:
+		err = tx.QueryRowContext(ctx, `INSERT INTO position (name, salary) VALUES ($1, $2) RETURNING id`, name, salary).Scan(&id)
+	case webhook.ActionEdit:
+		var result sql.Result
+		result, err = tx.ExecContext(ctx, `UPDATE position SET name = $1, salary = $2 WHERE id = $3`, name, salary, id)
+		if err == nil {
+			err = requireRowAffected(result, ErrPositionNotFound)
+		}
+	case webhook.ActionDelete:
+		var result sql.Result
+		result, err = tx.ExecContext(ctx, `DELETE FROM position WHERE id = $1`, id)
+		if err == nil {
+			err = requireRowAffected(result, ErrPositionNotFound)
+		}
+	default:
+		return 0, ErrUnknownAction
+	}
+	if err != nil {
+		if errors.Is(err, ErrPositionNotFound) {
+			return 0, err
+		}
+		return 0, fmt.Errorf("service: gagal mengelola position: %w", err)
+	}
+
+	position := model.Position{ID: id, Name: name, Salary: salary}
+	if err := webhook.Emit(ctx, tx, webhook.Event{Module: webhook.ModulePosition, Action: action, Data: position}); err != nil {
+		return 0, fmt.Errorf("service: gagal mengelola position: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("service: gagal mengelola position: %w", err)
+	}
+
+	return id, nil
+}
+
+// ManageEmployee melakukan add/edit/delete pada tabel employee dan
+// menyiarkan event lifecycle-nya. Password di-hash sebelum disimpan.
+func (s *Service) ManageEmployee(ctx context.Context, action string, id int, name string, positionID int, password string) (int, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("service: gagal mengelola employee: %w", err)
+	}
+	defer tx.Rollback()
+
+	switch action {
+	case webhook.ActionAdd:
+		var passwordHash string
+		passwordHash, err = auth.HashPassword(password)
+		if err != nil {
+			return 0, fmt.Errorf("service: gagal mengelola employee: %w", err)
+		}
+		err = tx.QueryRowContext(ctx,
+			`INSERT INTO employee (name, position_id, password_hash) VALUES ($1, $2, $3) RETURNING id`,
+			name, positionID, passwordHash).Scan(&id)
+	case webhook.ActionEdit:
+		var result sql.Result
+		result, err = tx.ExecContext(ctx, `UPDATE employee SET name = $1, position_id = $2 WHERE id = $3`, name, positionID, id)
+		if err == nil {
+			err = requireRowAffected(result, ErrEmployeeNotFound)
+		}
+	case webhook.ActionDelete:
+		var result sql.Result
+		result, err = tx.ExecContext(ctx, `DELETE FROM employee WHERE id = $1`, id)
+		if err == nil {
+			err = requireRowAffected(result, ErrEmployeeNotFound)
+		}
+	default:
+		return 0, ErrUnknownAction
+	}
+	if err != nil {
+		if errors.Is(err, ErrEmployeeNotFound) {
+			return 0, err
+		}
+		return 0, fmt.Errorf("service: gagal mengelola employee: %w", err)
+	}
+
+	employee := model.Employee{ID: id, Name: name, Position: model.Position{ID: positionID}}
+	if err := webhook.Emit(ctx, tx, webhook.Event{Module: webhook.ModuleEmployee, Action: action, Data: employee}); err != nil {
+		return 0, fmt.Errorf("service: gagal mengelola employee: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("service: gagal mengelola employee: %w", err)
+	}
+
+	return id, nil
+}
+
+// Login memverifikasi credential dan menerbitkan JWT untuk role yang
+// bersangkutan.
+func (s *Service) Login(ctx context.Context, jwtSecret string, role string, employeeID int, username, password string) (string, error) {
+	var id int
+	var passwordHash string
+
+	switch role {
+	case auth.RoleEmployee:
+		if err := s.DB.QueryRowContext(ctx, `SELECT id, password_hash FROM employee WHERE id = $1`, employeeID).Scan(&id, &passwordHash); err != nil {
+			return "", ErrInvalidCredential
+		}
+	case auth.RoleAdmin:
+		if err := s.DB.QueryRowContext(ctx, `SELECT id, password_hash FROM admin_user WHERE username = $1`, username).Scan(&id, &passwordHash); err != nil {
+			return "", ErrInvalidCredential
+		}
+	default:
+		return "", ErrUnknownAction
+	}
+
+	if !auth.CheckPassword(passwordHash, password) {
+		return "", ErrInvalidCredential
+	}
+
+	return auth.GenerateToken(jwtSecret, id, role)
+}
+
+// AccountBalance menghitung balance sebuah akun ledger dari path segment
+// `/accounts/{id}`.
+func (s *Service) AccountBalance(ctx context.Context, id string) (string, float64, error) {
+	account, err := ledger.AccountFromID(id)
+	if err != nil {
+		return "", 0, err
+	}
+	balance, err := ledger.Balance(ctx, s.DB, account)
+	if err != nil {
+		return "", 0, err
+	}
+	return account, balance, nil
+}
+
+// RunPayroll menjalankan batch payroll run untuk sebuah period.
+func (s *Service) RunPayroll(ctx context.Context, period string) (*payroll.Run, []payroll.Disbursement, error) {
+	return payroll.RunPayroll(ctx, s.DB, period)
+}
+
+// GetPayrollRun mengembalikan detail sebuah payroll run.
+func (s *Service) GetPayrollRun(ctx context.Context, runID int) (*payroll.Run, []payroll.Disbursement, error) {
+	return payroll.GetRun(ctx, s.DB, runID)
+}
+
+// Subscribe mendaftarkan subscriber webhook baru.
+func (s *Service) Subscribe(ctx context.Context, url, secret, eventFilter string) (*webhook.Subscription, error) {
+	return webhook.Subscribe(ctx, s.DB, url, secret, eventFilter)
+}
+
+// ListDeliveries mengembalikan status pengiriman webhook untuk inspeksi.
+func (s *Service) ListDeliveries(ctx context.Context) ([]webhook.Delivery, error) {
+	return webhook.ListDeliveries(ctx, s.DB)
+}
@@ -0,0 +1,277 @@
+// Package webhook mengimplementasikan transactional outbox pattern untuk
+// event lifecycle employee/position/balance. Event ditulis ke tabel outbox
+// dalam transaksi yang sama dengan perubahan state, lalu worker di
+// background yang mengirimkannya ke subscriber terdaftar lewat HTTP POST
+// dengan signature HMAC-SHA256 dan exponential backoff.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Module/action yang dikenal subsistem ini. Subscriber boleh memfilter
+// dengan nama module, "module:action" spesifik, atau "*" untuk seluruh
+// event; filter berupa nama action saja tidak cocok dengan event apa pun.
+const (
+	ModuleEmployee = "employee"
+	ModulePosition = "position"
+	ModuleBalance  = "balance"
+
+	ActionAdd      = "add"
+	ActionEdit     = "edit"
+	ActionDelete   = "delete"
+	ActionWithdraw = "withdraw"
+	ActionTopUp    = "topup"
+	ActionDeduct   = "deduct"
+)
+
+// maxAttempts membatasi jumlah percobaan pengiriman sebelum delivery
+// dianggap gagal permanen.
+const maxAttempts = 6
+
+// execer disatukan oleh *sql.DB dan *sql.Tx, sehingga Emit bisa dipanggil
+// di dalam transaksi caller agar penulisan outbox row ikut atomic dengan
+// perubahan state yang memicunya.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Event merepresentasikan satu kejadian lifecycle yang disiarkan ke
+// subscriber.
+type Event struct {
+	Module string `json:"module"`
+	Action string `json:"action"`
+	Data   any    `json:"data"`
+}
+
+// Emit menulis Event ke tabel outbox. Dipanggil dengan *sql.Tx yang sedang
+// dipakai untuk menyimpan perubahan state terkait, supaya event dan state
+// commit atau rollback bersama-sama.
+func Emit(ctx context.Context, exec execer, event Event) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return fmt.Errorf("webhook: gagal marshal event data: %w", err)
+	}
+	const insert = `
+		INSERT INTO outbox (module, action, data, created_at)
+		VALUES ($1, $2, $3, $4)
+	`
+	if _, err := exec.ExecContext(ctx, insert, event.Module, event.Action, data, time.Now()); err != nil {
+		return fmt.Errorf("webhook: gagal menulis outbox: %w", err)
+	}
+	return nil
+}
+
+// Subscription adalah pendaftaran satu subscriber eksternal.
+type Subscription struct {
+	ID          int    `json:"id"`
+	URL         string `json:"url"`
+	Secret      string `json:"-"`
+	EventFilter string `json:"event_filter"`
+}
+
+// Subscribe mendaftarkan subscriber baru. EventFilter berupa "*", nama
+// module ("employee"), atau "module:action" ("employee:delete").
+func Subscribe(ctx context.Context, db *sql.DB, url, secret, eventFilter string) (*Subscription, error) {
+	if eventFilter == "" {
+		eventFilter = "*"
+	}
+	sub := &Subscription{URL: url, Secret: secret, EventFilter: eventFilter}
+	const insert = `
+		INSERT INTO webhook_subscription (url, secret, event_filter)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`
+	if err := db.QueryRowContext(ctx, insert, url, secret, eventFilter).Scan(&sub.ID); err != nil {
+		return nil, fmt.Errorf("webhook: gagal mendaftarkan subscription: %w", err)
+	}
+	return sub, nil
+}
+
+// Delivery adalah status pengiriman satu Event ke satu Subscription.
+type Delivery struct {
+	ID             int       `json:"id"`
+	OutboxID       int       `json:"outbox_id"`
+	SubscriptionID int       `json:"subscription_id"`
+	Module         string    `json:"module"`
+	Action         string    `json:"action"`
+	URL            string    `json:"url"`
+	Attempts       int       `json:"attempts"`
+	Delivered      bool      `json:"delivered"`
+	LastStatus     int       `json:"last_status"`
+	LastError      string    `json:"last_error,omitempty"`
+	NextAttemptAt  time.Time `json:"next_attempt_at"`
+}
+
+// ListDeliveries mengembalikan seluruh delivery untuk keperluan inspeksi.
+func ListDeliveries(ctx context.Context, db *sql.DB) ([]Delivery, error) {
+	const query = `
+		SELECT d.id, d.outbox_id, d.subscription_id, o.module, o.action, s.url,
+			d.attempts, d.delivered, COALESCE(d.last_status, 0), COALESCE(d.last_error, ''), d.next_attempt_at
+		FROM webhook_delivery AS d
+		INNER JOIN outbox AS o ON d.outbox_id = o.id
+		INNER JOIN webhook_subscription AS s ON d.subscription_id = s.id
+		ORDER BY d.id DESC
+	`
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("webhook: gagal membaca deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []Delivery
+	for rows.Next() {
+		var d Delivery
+		if err := rows.Scan(&d.ID, &d.OutboxID, &d.SubscriptionID, &d.Module, &d.Action, &d.URL,
+			&d.Attempts, &d.Delivered, &d.LastStatus, &d.LastError, &d.NextAttemptAt); err != nil {
+			return nil, fmt.Errorf("webhook: gagal membaca baris delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, nil
+}
+
+// enqueuePendingDeliveries membuat webhook_delivery untuk setiap pasangan
+// outbox row x subscription yang cocok filter-nya dan belum memiliki
+// delivery row.
+func enqueuePendingDeliveries(ctx context.Context, db *sql.DB) error {
+	const insert = `
+		INSERT INTO webhook_delivery (outbox_id, subscription_id, next_attempt_at)
+		SELECT o.id, s.id, $1
+		FROM outbox AS o
+		CROSS JOIN webhook_subscription AS s
+		WHERE (s.event_filter = '*' OR s.event_filter = o.module OR s.event_filter = o.module || ':' || o.action)
+		AND NOT EXISTS (
+			SELECT 1 FROM webhook_delivery AS d WHERE d.outbox_id = o.id AND d.subscription_id = s.id
+		)
+	`
+	_, err := db.ExecContext(ctx, insert, time.Now())
+	return err
+}
+
+// dueDelivery adalah satu delivery yang siap dikirim/dicoba ulang, beserta
+// data yang dibutuhkan untuk mengirim request.
+type dueDelivery struct {
+	ID     int
+	URL    string
+	Secret string
+	Body   []byte
+}
+
+func fetchDueDeliveries(ctx context.Context, db *sql.DB) ([]dueDelivery, error) {
+	const query = `
+		SELECT d.id, s.url, s.secret, o.module, o.action, o.data
+		FROM webhook_delivery AS d
+		INNER JOIN outbox AS o ON d.outbox_id = o.id
+		INNER JOIN webhook_subscription AS s ON d.subscription_id = s.id
+		WHERE d.delivered = false AND d.attempts < $1 AND d.next_attempt_at <= $2
+	`
+	rows, err := db.QueryContext(ctx, query, maxAttempts, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var due []dueDelivery
+	for rows.Next() {
+		var d dueDelivery
+		var module, action string
+		var data json.RawMessage
+		if err := rows.Scan(&d.ID, &d.URL, &d.Secret, &module, &action, &data); err != nil {
+			return nil, err
+		}
+		body, err := json.Marshal(Event{Module: module, Action: action, Data: json.RawMessage(data)})
+		if err != nil {
+			return nil, err
+		}
+		d.Body = body
+		due = append(due, d)
+	}
+	return due, nil
+}
+
+// sign menghasilkan signature HMAC-SHA256 hex dari body memakai secret milik
+// subscriber, dikirim lewat header X-Webhook-Signature.
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// backoff menghitung jeda sebelum percobaan berikutnya: 2^attempts menit.
+func backoff(attempts int) time.Duration {
+	return time.Duration(1<<uint(attempts)) * time.Minute
+}
+
+func attemptDelivery(ctx context.Context, db *sql.DB, client *http.Client, d dueDelivery) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Body))
+	if err != nil {
+		recordFailure(ctx, db, d.ID, 0, err.Error())
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Signature", sign(d.Secret, d.Body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		recordFailure(ctx, db, d.ID, 0, err.Error())
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		const markDelivered = `UPDATE webhook_delivery SET delivered = true, last_status = $1 WHERE id = $2`
+		db.ExecContext(ctx, markDelivered, resp.StatusCode, d.ID)
+		return
+	}
+	recordFailure(ctx, db, d.ID, resp.StatusCode, fmt.Sprintf("unexpected status %d", resp.StatusCode))
+}
+
+func recordFailure(ctx context.Context, db *sql.DB, deliveryID int, status int, errMsg string) {
+	const query = `
+		UPDATE webhook_delivery
+		SET attempts = attempts + 1, last_status = $1, last_error = $2, next_attempt_at = $3
+		WHERE id = $4
+	`
+	var attempts int
+	db.QueryRowContext(ctx, `SELECT attempts FROM webhook_delivery WHERE id = $1`, deliveryID).Scan(&attempts)
+	db.ExecContext(ctx, query, status, errMsg, time.Now().Add(backoff(attempts)), deliveryID)
+}
+
+// StartDispatcher menjalankan worker background yang secara berkala
+// mengantrikan delivery baru dan mengirim delivery yang sudah due.
+func StartDispatcher(ctx context.Context, db *sql.DB) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	go func() {
+		ticker := time.NewTicker(5 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := enqueuePendingDeliveries(ctx, db); err != nil {
+					fmt.Printf("webhook: gagal enqueue delivery: %v\n", err)
+					continue
+				}
+				due, err := fetchDueDeliveries(ctx, db)
+				if err != nil {
+					fmt.Printf("webhook: gagal membaca due delivery: %v\n", err)
+					continue
+				}
+				for _, d := range due {
+					attemptDelivery(ctx, db, client, d)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,44 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+)
+
+func TestSign_MatchesHMACSHA256OfBody(t *testing.T) {
+	secret := "s3cr3t"
+	body := []byte(`{"module":"balance","action":"topup"}`)
+
+	got := sign(secret, body)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	want := hex.EncodeToString(mac.Sum(nil))
+
+	if got != want {
+		t.Fatalf("sign() = %q, want %q", got, want)
+	}
+}
+
+func TestSign_DiffersWhenSecretDiffers(t *testing.T) {
+	body := []byte(`{"module":"balance"}`)
+	if sign("secret-a", body) == sign("secret-b", body) {
+		t.Fatal("expected different secrets to produce different signatures")
+	}
+}
+
+func TestBackoff_DoublesPerAttempt(t *testing.T) {
+	for attempts, want := range map[int]time.Duration{
+		0: 1 * time.Minute,
+		1: 2 * time.Minute,
+		2: 4 * time.Minute,
+		3: 8 * time.Minute,
+	} {
+		if got := backoff(attempts); got != want {
+			t.Fatalf("backoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}